@@ -3,6 +3,27 @@
 // This is a sample Go plugin that can be compiled to WASM
 // using TinyGo and loaded by the carapace gateway.
 //
+// It is built on carapace/plugin-sdk-go: tools are plain functions with
+// typed input/output structs, registered once in init(). The SDK derives
+// each tool's schema from its input struct's tags and handles the
+// HandleTool/GetInfo/Init/Shutdown WASI exports, so this file only
+// contains tool logic.
+//
+// This example also declares the "log" and "http" capabilities in
+// manifest.json and uses them: Init/Shutdown log through the gateway via
+// plugin.Log instead of WASI stdout, and fetchURL demonstrates an
+// outbound HTTP call via plugin.HTTPFetch so results never need to be
+// smuggled through tool arguments.
+//
+// echo is registered with RegisterStream instead of Register, so its
+// repetitions are pushed to the gateway one at a time via StreamContext
+// rather than buffered into a single result, and it stops early if the
+// gateway reports the call as cancelled.
+//
+// transformText uses carapace/plugin-sdk-go/text for all of its string
+// transforms, which operate on runes rather than bytes so multibyte
+// UTF-8 input is never corrupted.
+//
 // Prerequisites:
 //   - Install TinyGo: https://tinygo.org/getting-started/install/
 //
@@ -14,319 +35,195 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"strconv"
+
+	plugin "carapace/plugin-sdk-go"
+	"carapace/plugin-sdk-go/text"
 )
 
-// Required plugin metadata
-var PluginName = "go-plugin"
-var PluginVersion = "0.1.0"
-var PluginDescription = "A Go plugin example demonstrating WASM plugin development"
+func init() {
+	plugin.Info("go-plugin", "0.1.0", "A Go plugin example demonstrating WASM plugin development")
 
-// greetInput represents input for the greet tool
+	plugin.Register("greet", "Generate a personalized greeting", greet)
+	plugin.Register("calculate", "Perform a simple calculation", calculate)
+	plugin.RegisterStream("echo", "Echo back a message with optional repetition, one chunk per repeat", echo)
+	plugin.Register("transformText", "Apply text transformations", transformText)
+	plugin.Register("fetchURL", "Fetch a URL and return the response body", fetchURL)
+
+	plugin.OnInit(func() error {
+		plugin.Log(plugin.LogInfo, fmt.Sprintf("%s initialized", "go-plugin"))
+		return nil
+	})
+	plugin.OnShutdown(func() error {
+		plugin.Log(plugin.LogInfo, fmt.Sprintf("%s shutdown", "go-plugin"))
+		return nil
+	})
+}
+
+// greetInput is the input for the greet tool.
 type greetInput struct {
-	Name    string `json:"name"`
-	Prefix  string `json:"prefix,omitempty"`
+	Name   string `json:"name" description:"Name to greet"`
+	Prefix string `json:"prefix,omitempty" description:"Greeting prefix (optional)" default:"Hello"`
 }
 
-// calculateInput represents input for the calculate tool
-type calculateInput struct {
-	A        float64 `json:"a"`
-	B        float64 `json:"b"`
-	Operation string `json:"operation"`
+type greetOutput struct {
+	Greeting string `json:"greeting"`
+	Length   int    `json:"length"`
 }
 
 // Tool: greet - Generate a personalized greeting
-func greet(name string, prefix string) map[string]interface{} {
+func greet(ctx plugin.Context, in greetInput) (greetOutput, error) {
+	prefix := in.Prefix
 	if prefix == "" {
 		prefix = "Hello"
 	}
-	greeting := fmt.Sprintf("%s, %s!", prefix, name)
-	return map[string]interface{}{
-		"greeting":  greeting,
-		"length":    len(greeting),
-		"uppercase": greeting,
-	}
+	greeting := fmt.Sprintf("%s, %s!", prefix, in.Name)
+	return greetOutput{Greeting: greeting, Length: len(greeting)}, nil
+}
+
+// calculateInput is the input for the calculate tool.
+type calculateInput struct {
+	A         float64 `json:"a" description:"First number"`
+	B         float64 `json:"b" description:"Second number"`
+	Operation string  `json:"operation" description:"Operation: add, subtract, multiply, divide"`
+}
+
+type calculateOutput struct {
+	Operation string  `json:"operation"`
+	A         float64 `json:"a"`
+	B         float64 `json:"b"`
+	Result    float64 `json:"result"`
 }
 
 // Tool: calculate - Perform a simple calculation
-func calculate(a, b float64, operation string) map[string]interface{} {
+func calculate(ctx plugin.Context, in calculateInput) (calculateOutput, error) {
 	var result float64
-
-	switch operation {
+	switch in.Operation {
 	case "add":
-		result = a + b
+		result = in.A + in.B
 	case "subtract":
-		result = a - b
+		result = in.A - in.B
 	case "multiply":
-		result = a * b
+		result = in.A * in.B
 	case "divide":
-		if b != 0 {
-			result = a / b
+		if in.B == 0 {
+			return calculateOutput{}, plugin.NewError("divide_by_zero", "cannot divide by zero")
 		}
+		result = in.A / in.B
+	default:
+		return calculateOutput{}, plugin.NewError("bad_input", fmt.Sprintf("unknown operation: %s", in.Operation))
 	}
 
-	return map[string]interface{}{
-		"operation": operation,
-		"a":         a,
-		"b":         b,
-		"result":    result,
-	}
+	return calculateOutput{Operation: in.Operation, A: in.A, B: in.B, Result: result}, nil
 }
 
-// Tool: echo - Echo back a message with optional repetition
-func echo(message string, repeat int) map[string]interface{} {
-	if repeat < 1 {
-		repeat = 1
-	}
-	repeated := ""
-	for i := 0; i < repeat; i++ {
-		repeated += message + " "
-	}
-	repeated = repeated[:len(repeated)-1] // Remove trailing space
-
-	return map[string]interface{}{
-		"original":  message,
-		"repeated":  repeated,
-		"repeatCount": repeat,
-	}
+// fetchURLInput is the input for the fetchURL tool.
+type fetchURLInput struct {
+	URL string `json:"url" description:"URL to fetch"`
 }
 
-// Tool: getInfo - Return information about this plugin
-func getInfo() map[string]interface{} {
-	return map[string]interface{}{
-		"name":        PluginName,
-		"version":     PluginVersion,
-		"description": PluginDescription,
-		"tools": []map[string]interface{}{
-			{
-				"name":        "greet",
-				"description": "Generate a personalized greeting",
-				"params": map[string]interface{}{
-					"name":   map[string]string{"type": "string", "description": "Name to greet"},
-					"prefix": map[string]string{"type": "string", "description": "Greeting prefix (optional)"},
-				},
-			},
-			{
-				"name":        "calculate",
-				"description": "Perform a simple calculation",
-				"params": map[string]interface{}{
-					"a":        map[string]string{"type": "number", "description": "First number"},
-					"b":        map[string]string{"type": "number", "description": "Second number"},
-					"operation": map[string]string{"type": "string", "description": "Operation: add, subtract, multiply, divide"},
-				},
-			},
-			{
-				"name":        "echo",
-				"description": "Echo back a message with optional repetition",
-				"params": map[string]interface{}{
-					"message": map[string]string{"type": "string", "description": "Message to echo"},
-					"repeat":  map[string]string{"type": "number", "description": "Number of times to repeat (default: 1)"},
-				},
-			},
-			{
-				"name":        "getInfo",
-				"description": "Get plugin information",
-				"params":      map[string]interface{}{},
-			},
-		},
-	}
+type fetchURLOutput struct {
+	Body string `json:"body"`
 }
 
-// Tool: transformText - Apply text transformations
-func transformText(text string, options string) map[string]interface{} {
-	var opts map[string]interface{}
-	json.Unmarshal([]byte(options), &opts)
-
-	uppercase := false
-	lowercase := false
-	reverse := false
-	trim := true
-	capitalize := false
-
-	if v, ok := opts["uppercase"].(bool); ok {
-		uppercase = v
-	}
-	if v, ok := opts["lowercase"].(bool); ok {
-		lowercase = v
-	}
-	if v, ok := opts["reverse"].(bool); ok {
-		reverse = v
-	}
-	if v, ok := opts["trim"].(bool); ok {
-		trim = v
-	}
-	if v, ok := opts["capitalize"].(bool); ok {
-		capitalize = v
-	}
-
-	result := text
-	if trim {
-		result = removeSpaces(result)
-	}
-	if uppercase {
-		result = toUpper(result)
-	} else if lowercase {
-		result = toLower(result)
-	} else if capitalize {
-		result = capitalizeFirst(result)
-	}
-	if reverse {
-		result = reverseString(result)
+// Tool: fetchURL - Fetch a URL through the gateway's host_http_fetch import
+// and return the raw response body. Requires the "http" capability.
+func fetchURL(ctx plugin.Context, in fetchURLInput) (fetchURLOutput, error) {
+	reqJSON := fmt.Sprintf(`{"method":"GET","url":%q}`, in.URL)
+	body, err := plugin.HTTPFetch(reqJSON)
+	if err != nil {
+		return fetchURLOutput{}, err
 	}
+	return fetchURLOutput{Body: body}, nil
+}
 
-	return map[string]interface{}{
-		"original":   text,
-		"transformed": result,
-		"options":    opts,
-	}
+// echoInput is the input for the echo tool.
+type echoInput struct {
+	Message string `json:"message" description:"Message to echo"`
+	Repeat  int    `json:"repeat,omitempty" description:"Number of times to repeat" default:"1"`
 }
 
-// Helper functions for string manipulation
-func removeSpaces(s string) string {
-	result := ""
-	for _, c := range s {
-		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
-			result += string(c)
-		}
-	}
-	return result
+// echoChunk is one streamed repetition of the echo tool's result.
+type echoChunk struct {
+	Index   int    `json:"index"`
+	Message string `json:"message"`
 }
 
-func toUpper(s string) string {
-	result := ""
-	for _, c := range s {
-		if c >= 'a' && c <= 'z' {
-			result += string(c - 32)
-		} else {
-			result += string(c)
-		}
+// Tool: echo - Echo back a message with optional repetition, streamed one
+// chunk per repetition rather than buffered into a single result, so a
+// large repeat count is forwarded to the gateway's client as it's
+// produced. Checks Cancelled between chunks so a disconnected client
+// stops the loop promptly instead of running it to completion unseen.
+func echo(ctx plugin.StreamContext, in echoInput) error {
+	repeat := in.Repeat
+	if repeat < 1 {
+		repeat = 1
 	}
-	return result
-}
 
-func toLower(s string) string {
-	result := ""
-	for _, c := range s {
-		if c >= 'A' && c <= 'Z' {
-			result += string(c + 32)
-		} else {
-			result += string(c)
+	for i := 0; i < repeat; i++ {
+		if ctx.Cancelled() {
+			return nil
+		}
+		if err := ctx.Emit(echoChunk{Index: i, Message: in.Message}); err != nil {
+			return err
 		}
 	}
-	return result
+	return nil
 }
 
-func capitalizeFirst(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-	return toUpper(string(s[0])) + s[1:]
+// transformTextInput is the input for the transformText tool.
+type transformTextInput struct {
+	Text       string `json:"text" description:"Text to transform"`
+	Uppercase  bool   `json:"uppercase,omitempty" description:"Convert to uppercase"`
+	Lowercase  bool   `json:"lowercase,omitempty" description:"Convert to lowercase"`
+	Reverse    bool   `json:"reverse,omitempty" description:"Reverse the text"`
+	Trim       bool   `json:"trim,omitempty" description:"Trim leading/trailing whitespace" default:"true"`
+	Capitalize bool   `json:"capitalize,omitempty" description:"Capitalize the first character"`
+	Normalize  bool   `json:"normalize,omitempty" description:"Compose combining diacritics (NFC) before other transforms"`
 }
 
-func reverseString(s string) string {
-	result := ""
-	for i := len(s) - 1; i >= 0; i-- {
-		result += string(s[i])
-	}
-	return result
+type transformTextOutput struct {
+	Original    string `json:"original"`
+	Transformed string `json:"transformed"`
 }
 
-// Main entry point - handle tool calls from carapace
-//export HandleTool
-func HandleTool(toolName string, argsJSON string) string {
-	var args map[string]interface{}
-	if argsJSON != "" {
-		json.Unmarshal([]byte(argsJSON), &args)
+// Tool: transformText - Apply text transformations. Operates on runes via
+// carapace/plugin-sdk-go/text throughout, so multibyte UTF-8 input such as
+// CJK text, combining accents, and emoji survive every transform intact.
+func transformText(ctx plugin.Context, in transformTextInput) (transformTextOutput, error) {
+	result := in.Text
+	if in.Normalize {
+		result = text.NormalizeNFC(result)
 	}
-
-	switch toolName {
-	case "greet":
-		name := ""
-		prefix := ""
-		if n, ok := args["name"].(string); ok {
-			name = n
-		}
-		if p, ok := args["prefix"].(string); ok {
-			prefix = p
-		}
-		result, _ := json.Marshal(greet(name, prefix))
-		return string(result)
-
-	case "calculate":
-		var a, b float64
-		operation := "add"
-		if val, ok := args["a"].(float64); ok {
-			a = val
-		}
-		if val, ok := args["b"].(float64); ok {
-			b = val
-		}
-		if val, ok := args["operation"].(string); ok {
-			operation = val
-		}
-		result, _ := json.Marshal(calculate(a, b, operation))
-		return string(result)
-
-	case "echo":
-		message := ""
-		repeat := 1
-		if m, ok := args["message"].(string); ok {
-			message = m
-		}
-		if r, ok := args["repeat"].(float64); ok {
-			repeat = int(r)
-		}
-		result, _ := json.Marshal(echo(message, repeat))
-		return string(result)
-
-	case "getInfo":
-		result, _ := json.Marshal(getInfo())
-		return string(result)
-
-	case "transformText":
-		text := ""
-		options := "{}"
-		if t, ok := args["text"].(string); ok {
-			text = t
-		}
-		if o, ok := args["options"].(string); ok {
-			options = o
-		} else if o, ok := args["options"].(map[string]interface{}); ok {
-			options, _ = json.Marshal(o)
-		}
-		result, _ := json.Marshal(transformText(text, options))
-		return string(result)
-
-	default:
-		return `{"error": "Unknown tool: ` + toolName + `"}`
+	if in.Trim {
+		result = text.TrimSpace(result)
+	}
+	if in.Uppercase {
+		result = text.Upper(result)
+	} else if in.Lowercase {
+		result = text.Lower(result)
+	} else if in.Capitalize {
+		result = capitalizeFirstRune(result)
+	}
+	if in.Reverse {
+		result = text.ReverseRunes(result)
 	}
-}
-
-// Get plugin info - exported for discovery
-//export GetInfo
-func GetInfo() string {
-	result, _ := json.Marshal(getInfo())
-	return string(result)
-}
 
-// Init - called when plugin is loaded
-//export Init
-func Init() int {
-	fmt.Printf("%s v%s initialized\n", PluginName, PluginVersion)
-	return 0
+	return transformTextOutput{Original: in.Text, Transformed: result}, nil
 }
 
-// Shutdown - called when plugin is unloaded
-//export Shutdown
-func Shutdown() int {
-	fmt.Printf("%s shutdown\n", PluginName)
-	return 0
+// capitalizeFirstRune uppercases only the first rune of s, leaving the
+// rest untouched; text.Title is word-at-a-time and so too broad here.
+func capitalizeFirstRune(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	return text.Upper(string(runes[0])) + string(runes[1:])
 }
 
 // Required for TinyGo WASI
 func main() {
 	// main() is required for TinyGo but not used directly
-	// The actual entry point is via exported functions
+	// The actual entry point is via the SDK's exported functions
 }
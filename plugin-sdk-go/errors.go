@@ -0,0 +1,39 @@
+package plugin
+
+import "encoding/json"
+
+// Error is the canonical error shape returned to the gateway when a tool
+// handler fails. Handlers may return a *Error directly to control Code;
+// any other error is wrapped with Code "internal".
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError constructs a plugin Error with the given machine-readable code.
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+type errorEnvelope struct {
+	Err *Error `json:"error"`
+}
+
+// errorEnvelopeJSON wraps any error into the {"error": {"code", "message"}}
+// envelope the gateway expects from every tool call, whether it failed or
+// succeeded.
+func errorEnvelopeJSON(err error) string {
+	pluginErr, ok := err.(*Error)
+	if !ok {
+		pluginErr = &Error{Code: "internal", Message: err.Error()}
+	}
+	raw, marshalErr := json.Marshal(errorEnvelope{Err: pluginErr})
+	if marshalErr != nil {
+		return `{"error":{"code":"internal","message":"failed to encode error"}}`
+	}
+	return string(raw)
+}
@@ -0,0 +1,66 @@
+package plugin
+
+// HandleTool is the WASI export the carapace gateway calls to invoke a
+// registered tool. It always returns a JSON string: either the handler's
+// encoded result or a canonical {"error": {"code", "message"}} envelope.
+//
+//export HandleTool
+func HandleTool(callID, toolName, argsJSON string) string {
+	return dispatch(callID, toolName, argsJSON)
+}
+
+// HandleToolStream is the WASI export the gateway calls to invoke a
+// streaming tool. The plugin pushes partial results back via the
+// StreamContext the registered handler receives, then HandleToolStream
+// reports a terminal status once the handler returns.
+//
+//export HandleToolStream
+func HandleToolStream(callID, toolName, argsJSON string) int {
+	return dispatchStream(callID, toolName, argsJSON)
+}
+
+// HandleCancel is the WASI export the gateway calls when the client for a
+// streaming call has disconnected. It marks callID cancelled so the
+// handler's next StreamContext.Cancelled check returns true.
+//
+//export HandleCancel
+func HandleCancel(callID string) {
+	cancelStream(callID)
+}
+
+// GetInfo is the WASI export the gateway calls to discover this plugin's
+// metadata and tool schemas.
+//
+//export GetInfo
+func GetInfo() string {
+	return infoJSON()
+}
+
+// Init is the WASI export the gateway calls once, after instantiating the
+// module and before routing any tool calls to it. Registered OnInit hooks
+// run in order; the first error aborts Init and returns -1.
+//
+//export Init
+func Init() int {
+	for _, hook := range onInitHooks {
+		if err := hook(); err != nil {
+			return -1
+		}
+	}
+	return 0
+}
+
+// Shutdown is the WASI export the gateway calls before tearing the module
+// instance down. Registered OnShutdown hooks all run, in order, even if
+// one of them errors.
+//
+//export Shutdown
+func Shutdown() int {
+	status := 0
+	for _, hook := range onShutdownHooks {
+		if err := hook(); err != nil {
+			status = -1
+		}
+	}
+	return status
+}
@@ -0,0 +1,165 @@
+//go:build wasm
+
+package plugin
+
+import "unsafe"
+
+// This file declares the host functions the carapace gateway imports into
+// every WASM module, gated by the capabilities the plugin's manifest
+// declares (see Capability). Each import moves strings across the
+// host/guest boundary as a (pointer, length) pair. Results come back as a
+// packed (kind<<32 | len) status: the host stages the result bytes on its
+// side and the guest, now knowing the length, allocates its own buffer and
+// calls host_read_result to have the host copy into guest-owned memory.
+// This keeps every pointer that ever reaches unsafe.Pointer tied to a real
+// Go allocation, rather than reconstructing one from a bare integer the
+// host handed back. resultKind and unpackResult below are the only place
+// that packing is understood.
+
+// LogLevel identifies the severity of a Log call.
+type LogLevel int32
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+//go:wasmimport carapace host_log
+func hostLog(level int32, msgPtr unsafe.Pointer, msgLen uint32)
+
+// Log sends a structured log line to the gateway, which attributes it to
+// this plugin instance. Requires CapabilityLog.
+func Log(level LogLevel, msg string) {
+	ptr, ln := stringToPtr(msg)
+	hostLog(int32(level), ptr, ln)
+}
+
+//go:wasmimport carapace host_http_fetch
+func hostHTTPFetch(reqPtr unsafe.Pointer, reqLen uint32) uint64
+
+// HTTPFetch issues an outbound HTTP request on the plugin's behalf and
+// returns the raw JSON response body. reqJSON and the returned string are
+// both JSON objects shaped like {method, url, headers, body}; this keeps
+// plugins from having to smuggle fetch results through tool arguments.
+// Requires CapabilityHTTP.
+func HTTPFetch(reqJSON string) (string, error) {
+	ptr, ln := stringToPtr(reqJSON)
+	return unpackResult(hostHTTPFetch(ptr, ln))
+}
+
+//go:wasmimport carapace host_kv_get
+func hostKVGet(nsPtr unsafe.Pointer, nsLen uint32, keyPtr unsafe.Pointer, keyLen uint32) uint64
+
+// KVGet reads a value previously stored with KVSet under namespace/key.
+// It returns ok=false if no value is set. Requires CapabilityKV.
+func KVGet(namespace, key string) (value string, ok bool, err error) {
+	nsPtr, nsLen := stringToPtr(namespace)
+	keyPtr, keyLen := stringToPtr(key)
+	packed := hostKVGet(nsPtr, nsLen, keyPtr, keyLen)
+	if resultKind(packed>>32) == resultNotFound {
+		return "", false, nil
+	}
+	value, err = unpackResult(packed)
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+//go:wasmimport carapace host_kv_set
+func hostKVSet(nsPtr unsafe.Pointer, nsLen uint32, keyPtr unsafe.Pointer, keyLen uint32, valPtr unsafe.Pointer, valLen uint32) uint64
+
+// KVSet persists value under namespace/key for later retrieval with KVGet.
+// Requires CapabilityKV.
+func KVSet(namespace, key, value string) error {
+	nsPtr, nsLen := stringToPtr(namespace)
+	keyPtr, keyLen := stringToPtr(key)
+	valPtr, valLen := stringToPtr(value)
+	_, err := unpackResult(hostKVSet(nsPtr, nsLen, keyPtr, keyLen, valPtr, valLen))
+	return err
+}
+
+//go:wasmimport carapace host_secret_get
+func hostSecretGet(namePtr unsafe.Pointer, nameLen uint32) uint64
+
+// SecretGet reads a secret the plugin's manifest has declared it needs, by
+// name, from the gateway's secret store. Requires CapabilitySecret.
+func SecretGet(name string) (string, error) {
+	ptr, ln := stringToPtr(name)
+	return unpackResult(hostSecretGet(ptr, ln))
+}
+
+//go:wasmimport carapace host_now_unix_ms
+func hostNowUnixMS() int64
+
+// NowUnixMilli returns the gateway's current time in Unix milliseconds.
+// WASI has no reliable wall clock of its own, so plugins that need one
+// must go through the host. Requires CapabilityClock.
+func NowUnixMilli() int64 {
+	return hostNowUnixMS()
+}
+
+// stringToPtr exposes a Go string's backing bytes to a host import without
+// copying. The returned pointer is only valid for the duration of the host
+// call that receives it.
+func stringToPtr(s string) (unsafe.Pointer, uint32) {
+	if len(s) == 0 {
+		return nil, 0
+	}
+	return unsafe.Pointer(unsafe.StringData(s)), uint32(len(s))
+}
+
+// bytesToPtr exposes a Go byte slice's backing array to a host import
+// without copying, for the host to write a result into. Unlike
+// reconstructing a pointer from an address the host hands back, this
+// pointer is derived directly from a live Go allocation, so it never needs
+// an unsafe.Pointer/uintptr round trip to use.
+func bytesToPtr(b []byte) (unsafe.Pointer, uint32) {
+	if len(b) == 0 {
+		return nil, 0
+	}
+	return unsafe.Pointer(unsafe.SliceData(b)), uint32(len(b))
+}
+
+//go:wasmimport carapace host_read_result
+func hostReadResult(bufPtr unsafe.Pointer, bufLen uint32)
+
+// resultKind is the high 32 bits of a packed host call result: whether the
+// call succeeded, found nothing, or failed. The low 32 bits are the byte
+// length of the result the host has staged, which readResult then copies
+// into a guest-allocated buffer via host_read_result.
+type resultKind uint32
+
+const (
+	resultOK       resultKind = 0
+	resultNotFound resultKind = 1
+	resultFailed   resultKind = 2
+)
+
+// readResult allocates a buffer of n bytes and has the host copy its
+// currently staged result into it. Called at most once per host call,
+// immediately after that call returns.
+func readResult(n uint32) string {
+	if n == 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	ptr, ln := bytesToPtr(buf)
+	hostReadResult(ptr, ln)
+	return string(buf)
+}
+
+// unpackResult decodes a packed (kind<<32 | len) host call result into a Go
+// string, reading the staged result via readResult, or into a canonical
+// *Error if the host reported failure.
+func unpackResult(packed uint64) (string, error) {
+	kind := resultKind(packed >> 32)
+	ln := uint32(packed)
+
+	if kind == resultFailed {
+		return "", NewError("host_error", readResult(ln))
+	}
+	return readResult(ln), nil
+}
@@ -0,0 +1,20 @@
+package plugin
+
+// Capability names a host function group a plugin's manifest may request.
+// The gateway only imports the host functions for capabilities a plugin's
+// manifest declares; calling an ungranted host function fails at
+// instantiation time rather than silently no-opping.
+type Capability string
+
+const (
+	// CapabilityLog grants Log.
+	CapabilityLog Capability = "log"
+	// CapabilityHTTP grants HTTPFetch.
+	CapabilityHTTP Capability = "http"
+	// CapabilityKV grants KVGet and KVSet.
+	CapabilityKV Capability = "kv"
+	// CapabilitySecret grants SecretGet.
+	CapabilitySecret Capability = "secret"
+	// CapabilityClock grants NowUnixMilli.
+	CapabilityClock Capability = "clock"
+)
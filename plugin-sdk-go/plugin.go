@@ -0,0 +1,142 @@
+// Package plugin is the Go SDK for carapace WASM plugins.
+//
+// Plugin authors register strongly-typed tool handlers with Register. The
+// SDK takes care of decoding arguments, deriving the tool schema from the
+// input struct's tags, encoding results, and wiring up the HandleTool,
+// GetInfo, Init and Shutdown WASI exports that the carapace gateway calls.
+// A plugin's main package should need nothing beyond an init() that calls
+// Info and Register, plus an empty main().
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Context carries per-call metadata into a tool handler.
+type Context struct {
+	// ToolName is the name the tool was registered under.
+	ToolName string
+	// CallID identifies this invocation, stable across a single call's
+	// lifetime. It is required to correlate streamed chunks and cancellation.
+	CallID string
+}
+
+// Handler is the typed signature plugin authors implement for a tool.
+// In and Out are plain structs; their JSON shape is derived from struct
+// tags the same way encoding/json already understands them.
+type Handler[In any, Out any] func(ctx Context, in In) (Out, error)
+
+type registeredTool struct {
+	description string
+	schema      map[string]interface{}
+	streaming   bool
+	// invoke is set by Register and left nil by RegisterStream; streaming
+	// tools are only callable through HandleToolStream; dispatch rejects
+	// them instead of calling a nil invoke.
+	invoke func(ctx Context, argsJSON string) (string, error)
+}
+
+var (
+	tools     = map[string]*registeredTool{}
+	toolOrder []string
+
+	pluginName        string
+	pluginVersion     string
+	pluginDescription string
+
+	onInitHooks     []func() error
+	onShutdownHooks []func() error
+)
+
+// OnInit registers a function to run when the gateway calls Init, after the
+// module is instantiated and before any tool calls are routed to it. Hooks
+// run in registration order; the first error aborts Init.
+func OnInit(hook func() error) {
+	onInitHooks = append(onInitHooks, hook)
+}
+
+// OnShutdown registers a function to run when the gateway calls Shutdown,
+// before the module instance is torn down. Hooks run in registration
+// order; errors are logged but do not stop later hooks from running.
+func OnShutdown(hook func() error) {
+	onShutdownHooks = append(onShutdownHooks, hook)
+}
+
+// Info declares the plugin-level metadata carapace surfaces via GetInfo.
+// Call it once, typically from an init() function.
+func Info(name, version, description string) {
+	pluginName = name
+	pluginVersion = version
+	pluginDescription = description
+}
+
+// Register associates a tool name with a typed handler. The parameter
+// schema reported by GetInfo is derived from the In struct's fields, so it
+// can never drift from what the handler actually accepts.
+func Register[In any, Out any](name, description string, handler Handler[In, Out]) {
+	var zero In
+	tools[name] = &registeredTool{
+		description: description,
+		schema:      deriveSchema(reflect.TypeOf(zero)),
+		invoke: func(ctx Context, argsJSON string) (string, error) {
+			var in In
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &in); err != nil {
+					return "", &Error{Code: "bad_input", Message: fmt.Sprintf("decoding arguments: %v", err)}
+				}
+			}
+			out, err := handler(ctx, in)
+			if err != nil {
+				return "", err
+			}
+			raw, err := json.Marshal(out)
+			if err != nil {
+				return "", &Error{Code: "internal", Message: fmt.Sprintf("encoding result: %v", err)}
+			}
+			return string(raw), nil
+		},
+	}
+	toolOrder = append(toolOrder, name)
+}
+
+// dispatch runs a registered tool by name and always returns a JSON string:
+// either the handler's encoded result or a canonical error envelope.
+func dispatch(callID, name, argsJSON string) string {
+	tool, ok := tools[name]
+	if !ok {
+		return errorEnvelopeJSON(&Error{Code: "unknown_tool", Message: fmt.Sprintf("unknown tool: %s", name)})
+	}
+	if tool.invoke == nil {
+		return errorEnvelopeJSON(&Error{Code: "streaming_tool", Message: fmt.Sprintf("%s is a streaming tool; call it via HandleToolStream", name)})
+	}
+	result, err := tool.invoke(Context{ToolName: name, CallID: callID}, argsJSON)
+	if err != nil {
+		return errorEnvelopeJSON(err)
+	}
+	return result
+}
+
+// infoJSON builds the GetInfo payload from the registered plugin metadata
+// and tool schemas.
+func infoJSON() string {
+	info := map[string]interface{}{
+		"name":        pluginName,
+		"version":     pluginVersion,
+		"description": pluginDescription,
+	}
+	toolList := make([]map[string]interface{}, 0, len(toolOrder))
+	for _, name := range toolOrder {
+		tool := tools[name]
+		toolList = append(toolList, map[string]interface{}{
+			"name":        name,
+			"description": tool.description,
+			"params":      tool.schema,
+			"streaming":   tool.streaming,
+		})
+	}
+	info["tools"] = toolList
+	raw, _ := json.Marshal(info)
+	return string(raw)
+}
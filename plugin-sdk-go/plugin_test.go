@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type echoArgs struct {
+	Message string `json:"message"`
+}
+
+type echoResult struct {
+	Message string `json:"message"`
+}
+
+func TestDispatchRoundTrip(t *testing.T) {
+	Register("plugin_test.echo", "echoes its input", func(ctx Context, in echoArgs) (echoResult, error) {
+		if ctx.ToolName != "plugin_test.echo" {
+			t.Errorf("ctx.ToolName = %q, want plugin_test.echo", ctx.ToolName)
+		}
+		if ctx.CallID != "call-1" {
+			t.Errorf("ctx.CallID = %q, want call-1", ctx.CallID)
+		}
+		return echoResult{Message: in.Message}, nil
+	})
+
+	raw := dispatch("call-1", "plugin_test.echo", `{"message":"hi"}`)
+
+	var got echoResult
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatalf("dispatch returned invalid JSON %q: %v", raw, err)
+	}
+	if got.Message != "hi" {
+		t.Errorf("dispatch result = %+v, want Message=hi", got)
+	}
+}
+
+func TestDispatchHandlerError(t *testing.T) {
+	Register("plugin_test.fails", "always fails", func(ctx Context, in echoArgs) (echoResult, error) {
+		return echoResult{}, NewError("boom", "it broke")
+	})
+
+	raw := dispatch("call-2", "plugin_test.fails", `{}`)
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		t.Fatalf("dispatch returned invalid JSON %q: %v", raw, err)
+	}
+	if envelope.Err == nil || envelope.Err.Code != "boom" || envelope.Err.Message != "it broke" {
+		t.Errorf("dispatch error envelope = %+v, want code=boom message=\"it broke\"", envelope.Err)
+	}
+}
+
+func TestDispatchUnknownTool(t *testing.T) {
+	raw := dispatch("call-3", "plugin_test.nope", `{}`)
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		t.Fatalf("dispatch returned invalid JSON %q: %v", raw, err)
+	}
+	if envelope.Err == nil || envelope.Err.Code != "unknown_tool" {
+		t.Errorf("dispatch error envelope = %+v, want code=unknown_tool", envelope.Err)
+	}
+}
+
+func TestDispatchRejectsStreamingTool(t *testing.T) {
+	RegisterStream("plugin_test.streamonly", "stream only", func(ctx StreamContext, in echoArgs) error {
+		return nil
+	})
+
+	raw := dispatch("call-4", "plugin_test.streamonly", `{}`)
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		t.Fatalf("dispatch returned invalid JSON %q: %v", raw, err)
+	}
+	if envelope.Err == nil || envelope.Err.Code != "streaming_tool" {
+		t.Errorf("dispatch error envelope = %+v, want code=streaming_tool", envelope.Err)
+	}
+}
+
+func TestErrorEnvelopeJSON(t *testing.T) {
+	raw := errorEnvelopeJSON(NewError("bad_input", "missing field"))
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		t.Fatalf("errorEnvelopeJSON returned invalid JSON %q: %v", raw, err)
+	}
+	if envelope.Err == nil || envelope.Err.Code != "bad_input" || envelope.Err.Message != "missing field" {
+		t.Errorf("envelope = %+v, want code=bad_input message=\"missing field\"", envelope.Err)
+	}
+}
+
+func TestErrorEnvelopeJSONWrapsPlainError(t *testing.T) {
+	raw := errorEnvelopeJSON(errPlain("unexpected"))
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		t.Fatalf("errorEnvelopeJSON returned invalid JSON %q: %v", raw, err)
+	}
+	if envelope.Err == nil || envelope.Err.Code != "internal" || envelope.Err.Message != "unexpected" {
+		t.Errorf("envelope = %+v, want code=internal message=unexpected", envelope.Err)
+	}
+}
+
+type errPlain string
+
+func (e errPlain) Error() string { return string(e) }
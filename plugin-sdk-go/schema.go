@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"reflect"
+	"strings"
+)
+
+// deriveSchema builds the "params" block reported by GetInfo from an input
+// struct's fields. Supported tags on each field:
+//
+//	json:"name,omitempty"    // parameter name and optionality from omitempty
+//	description:"..."        // human-readable description
+//	optional:"true"          // explicit optionality override
+//	default:"..."            // default value, echoed to callers
+func deriveSchema(t reflect.Type) map[string]interface{} {
+	params := map[string]interface{}{}
+	if t == nil || t.Kind() != reflect.Struct {
+		return params
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, omitempty := jsonTagName(field)
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		entry := map[string]interface{}{
+			"type": schemaType(field.Type),
+		}
+		if desc, ok := field.Tag.Lookup("description"); ok {
+			entry["description"] = desc
+		}
+		optional := omitempty
+		if v, ok := field.Tag.Lookup("optional"); ok {
+			optional = v == "true"
+		}
+		entry["optional"] = optional
+		if def, ok := field.Tag.Lookup("default"); ok {
+			entry["default"] = def
+		}
+
+		params[name] = entry
+	}
+
+	return params
+}
+
+func jsonTagName(field reflect.StructField) (name string, omitempty bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok || tag == "" {
+		return "", false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Ptr:
+		return schemaType(t.Elem())
+	default:
+		return "string"
+	}
+}
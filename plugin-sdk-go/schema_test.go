@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONTagName(t *testing.T) {
+	type fields struct {
+		Plain     string `json:"plain"`
+		Optional  string `json:"optional,omitempty"`
+		Dashed    string `json:"-"`
+		Untagged  string
+		MultiOpts string `json:"multi,omitempty,string"`
+	}
+
+	typ := reflect.TypeOf(fields{})
+	cases := []struct {
+		field         string
+		wantName      string
+		wantOmitempty bool
+	}{
+		{"Plain", "plain", false},
+		{"Optional", "optional", true},
+		{"Dashed", "-", false},
+		{"Untagged", "", false},
+		{"MultiOpts", "multi", true},
+	}
+
+	for _, c := range cases {
+		field, ok := typ.FieldByName(c.field)
+		if !ok {
+			t.Fatalf("no field %s on test struct", c.field)
+		}
+		name, omitempty := jsonTagName(field)
+		if name != c.wantName || omitempty != c.wantOmitempty {
+			t.Errorf("jsonTagName(%s) = (%q, %v), want (%q, %v)", c.field, name, omitempty, c.wantName, c.wantOmitempty)
+		}
+	}
+}
+
+func TestSchemaType(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		want  string
+	}{
+		{"", "string"},
+		{true, "boolean"},
+		{0, "number"},
+		{float64(0), "number"},
+		{[]string{}, "array"},
+		{struct{}{}, "object"},
+		{map[string]int{}, "object"},
+	}
+	for _, c := range cases {
+		got := schemaType(reflect.TypeOf(c.value))
+		if got != c.want {
+			t.Errorf("schemaType(%T) = %q, want %q", c.value, got, c.want)
+		}
+	}
+
+	var s *string
+	if got := schemaType(reflect.TypeOf(s)); got != "string" {
+		t.Errorf("schemaType(*string) = %q, want \"string\"", got)
+	}
+}
+
+func TestDeriveSchema(t *testing.T) {
+	type input struct {
+		Name     string `json:"name" description:"the name"`
+		Prefix   string `json:"prefix,omitempty" default:"Hello"`
+		Override string `json:"override" optional:"true"`
+		hidden   string
+	}
+
+	schema := deriveSchema(reflect.TypeOf(input{}))
+
+	name, ok := schema["name"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schema[name] missing or wrong type: %#v", schema["name"])
+	}
+	if name["type"] != "string" || name["description"] != "the name" || name["optional"] != false {
+		t.Errorf("schema[name] = %#v, want type=string description=\"the name\" optional=false", name)
+	}
+
+	prefix := schema["prefix"].(map[string]interface{})
+	if prefix["optional"] != true || prefix["default"] != "Hello" {
+		t.Errorf("schema[prefix] = %#v, want optional=true default=Hello (from omitempty)", prefix)
+	}
+
+	override := schema["override"].(map[string]interface{})
+	if override["optional"] != true {
+		t.Errorf("schema[override] = %#v, want optional=true (explicit tag overrides missing omitempty)", override)
+	}
+
+	if _, ok := schema["hidden"]; ok {
+		t.Errorf("schema contains unexported field hidden: %#v", schema)
+	}
+
+	if got := deriveSchema(reflect.TypeOf(0)); len(got) != 0 {
+		t.Errorf("deriveSchema(non-struct) = %#v, want empty", got)
+	}
+	if got := deriveSchema(nil); len(got) != 0 {
+		t.Errorf("deriveSchema(nil) = %#v, want empty", got)
+	}
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// StreamContext is the Context passed to a streaming tool handler. Emit
+// pushes one chunk of the result at a time; Cancelled reports whether the
+// gateway has asked the call to stop because the client disconnected.
+type StreamContext struct {
+	Context
+}
+
+// Emit sends one chunk of a streaming tool's result to the gateway, which
+// forwards it to its client over SSE, WebSocket or gRPC server-streaming
+// as it is produced, rather than waiting for the call to finish.
+func (c StreamContext) Emit(chunk interface{}) error {
+	raw, err := json.Marshal(chunk)
+	if err != nil {
+		return fmt.Errorf("encoding chunk: %w", err)
+	}
+	emitChunk(c.CallID, string(raw))
+	return nil
+}
+
+// Cancelled reports whether the gateway invoked HandleCancel for this
+// call. Handlers that emit many chunks should check it between chunks and
+// return promptly once it is true.
+func (c StreamContext) Cancelled() bool {
+	return cancelledCalls[c.CallID]
+}
+
+// StreamHandler is the typed signature for a streaming tool. It returns
+// once every chunk has been emitted, or once it has stopped cleanly after
+// observing Cancelled.
+type StreamHandler[In any] func(ctx StreamContext, in In) error
+
+type registeredStreamTool struct {
+	invoke func(callID, argsJSON string) error
+}
+
+var (
+	streamTools    = map[string]*registeredStreamTool{}
+	cancelledCalls = map[string]bool{}
+	activeCalls    = map[string]bool{}
+)
+
+// RegisterStream associates a tool name with a typed streaming handler.
+// As with Register, the parameter schema reported by GetInfo is derived
+// from the In struct's tags. A streaming tool's GetInfo entry reports
+// "streaming": true and has no HandleTool invoke path; HandleTool rejects
+// it with an error rather than calling into HandleToolStream's handler.
+func RegisterStream[In any](name, description string, handler StreamHandler[In]) {
+	var zero In
+	tools[name] = &registeredTool{
+		description: description,
+		schema:      deriveSchema(reflect.TypeOf(zero)),
+		streaming:   true,
+	}
+	toolOrder = append(toolOrder, name)
+
+	streamTools[name] = &registeredStreamTool{
+		invoke: func(callID, argsJSON string) error {
+			var in In
+			if argsJSON != "" {
+				if err := json.Unmarshal([]byte(argsJSON), &in); err != nil {
+					return &Error{Code: "bad_input", Message: fmt.Sprintf("decoding arguments: %v", err)}
+				}
+			}
+			return handler(StreamContext{Context{ToolName: name, CallID: callID}}, in)
+		},
+	}
+}
+
+// dispatchStream runs a registered streaming tool to completion and
+// always reports a terminal status via host_emit_end, even on an unknown
+// tool or a handler error. callID is tracked as active for the duration
+// of the call so a HandleCancel that arrives after it has already
+// finished is a no-op instead of leaking a cancelledCalls entry forever.
+func dispatchStream(callID, name, argsJSON string) int {
+	activeCalls[callID] = true
+	defer delete(activeCalls, callID)
+	defer delete(cancelledCalls, callID)
+
+	stream, ok := streamTools[name]
+	if !ok {
+		emitEnd(callID, streamStatusJSON(NewError("unknown_tool", fmt.Sprintf("unknown tool: %s", name))))
+		return -1
+	}
+
+	if err := stream.invoke(callID, argsJSON); err != nil {
+		emitEnd(callID, streamStatusJSON(err))
+		return -1
+	}
+
+	emitEnd(callID, streamStatusJSON(nil))
+	return 0
+}
+
+// cancelStream marks callID as cancelled so the next Cancelled() check in
+// that call's handler returns true. It is a no-op for a callID that isn't
+// currently dispatching, so a cancel that arrives just after the call has
+// already finished can't leak an entry into cancelledCalls.
+func cancelStream(callID string) {
+	if activeCalls[callID] {
+		cancelledCalls[callID] = true
+	}
+}
+
+type streamStatus struct {
+	Code string `json:"code"`
+	Err  *Error `json:"error,omitempty"`
+}
+
+// streamStatusJSON builds the status payload passed to host_emit_end: code
+// is "ok" on success, "error" otherwise, with the canonical error envelope.
+func streamStatusJSON(err error) string {
+	status := streamStatus{Code: "ok"}
+	if err != nil {
+		pluginErr, ok := err.(*Error)
+		if !ok {
+			pluginErr = &Error{Code: "internal", Message: err.Error()}
+		}
+		status.Code = "error"
+		status.Err = pluginErr
+	}
+	raw, marshalErr := json.Marshal(status)
+	if marshalErr != nil {
+		return `{"code":"error","error":{"code":"internal","message":"failed to encode status"}}`
+	}
+	return string(raw)
+}
@@ -0,0 +1,32 @@
+//go:build wasm
+
+package plugin
+
+import "unsafe"
+
+// host_emit_chunk and host_emit_end are the host imports a streaming tool
+// call uses to push partial results and then signal completion. They take
+// the call ID rather than the tool name because a single tool name can be
+// in flight for several concurrent callers at once.
+
+//go:wasmimport carapace host_emit_chunk
+func hostEmitChunk(callIDPtr unsafe.Pointer, callIDLen uint32, chunkPtr unsafe.Pointer, chunkLen uint32)
+
+//go:wasmimport carapace host_emit_end
+func hostEmitEnd(callIDPtr unsafe.Pointer, callIDLen uint32, statusPtr unsafe.Pointer, statusLen uint32)
+
+// emitChunk forwards one chunk of a streaming tool's result to the
+// gateway via host_emit_chunk.
+func emitChunk(callID, chunkJSON string) {
+	callIDPtr, callIDLen := stringToPtr(callID)
+	chunkPtr, chunkLen := stringToPtr(chunkJSON)
+	hostEmitChunk(callIDPtr, callIDLen, chunkPtr, chunkLen)
+}
+
+// emitEnd tells the gateway a streaming tool call has finished, whether it
+// succeeded, failed, or was cancelled, via host_emit_end.
+func emitEnd(callID, statusJSON string) {
+	callIDPtr, callIDLen := stringToPtr(callID)
+	statusPtr, statusLen := stringToPtr(statusJSON)
+	hostEmitEnd(callIDPtr, callIDLen, statusPtr, statusLen)
+}
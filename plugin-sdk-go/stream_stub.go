@@ -0,0 +1,31 @@
+//go:build !wasm
+
+package plugin
+
+// Non-wasm builds (native `go test ./...`) have no gateway to forward
+// emitted chunks to, so emitChunk/emitEnd record into these package-level
+// slices instead of calling out. stream_host.go provides the real
+// host-import-backed versions for the wasm target this package ships for.
+
+type emittedChunk struct {
+	callID string
+	json   string
+}
+
+type emittedEnd struct {
+	callID string
+	status string
+}
+
+var (
+	recordedChunks []emittedChunk
+	recordedEnds   []emittedEnd
+)
+
+func emitChunk(callID, chunkJSON string) {
+	recordedChunks = append(recordedChunks, emittedChunk{callID: callID, json: chunkJSON})
+}
+
+func emitEnd(callID, statusJSON string) {
+	recordedEnds = append(recordedEnds, emittedEnd{callID: callID, status: statusJSON})
+}
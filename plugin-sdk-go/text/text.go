@@ -0,0 +1,109 @@
+// Package text provides Unicode-correct text transforms for plugins
+// compiled with TinyGo's wasi target. It sticks to the standard library's
+// unicode package rather than golang.org/x/text, whose transform and
+// collation machinery TinyGo cannot currently link for wasi.
+//
+// NormalizeNFC is consequently not a full Unicode canonical composition:
+// it handles the common case of a base Latin letter followed by a single
+// combining diacritic (the shape produced by most input methods), not the
+// full Unicode Normalization Database. Text that is already precomposed,
+// or that doesn't use Latin combining marks, passes through unchanged.
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Upper returns s with every rune mapped to its uppercase form.
+func Upper(s string) string {
+	return strings.Map(unicode.ToUpper, s)
+}
+
+// Lower returns s with every rune mapped to its lowercase form.
+func Lower(s string) string {
+	return strings.Map(unicode.ToLower, s)
+}
+
+// Title returns s with the first rune of each word uppercased and the
+// rest lowercased, where a word is a maximal run of non-space runes.
+func Title(s string) string {
+	runes := []rune(s)
+	result := make([]rune, len(runes))
+	startOfWord := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			result[i] = r
+			startOfWord = true
+			continue
+		}
+		if startOfWord {
+			result[i] = unicode.ToUpper(r)
+		} else {
+			result[i] = unicode.ToLower(r)
+		}
+		startOfWord = false
+	}
+	return string(result)
+}
+
+// TrimSpace returns s with leading and trailing Unicode whitespace
+// removed.
+func TrimSpace(s string) string {
+	return strings.TrimFunc(s, unicode.IsSpace)
+}
+
+// ReverseRunes returns s with its runes in reverse order. Unlike reversing
+// bytes, this never splits a multibyte UTF-8 encoding, so the result is
+// always valid UTF-8. Combining marks are reversed along with their base
+// rune rather than kept attached to it; callers that need grapheme-aware
+// reversal should normalize with NormalizeNFC first.
+func ReverseRunes(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// combiningToPrecomposed maps a (base, combining mark) pair to its
+// precomposed equivalent for the common Latin letter + diacritic
+// combinations. See the package doc for why this isn't a full NFC table.
+var combiningToPrecomposed = map[[2]rune]rune{
+	{'a', '́'}: 'á', {'a', '̀'}: 'à', {'a', '̂'}: 'â', {'a', '̃'}: 'ã', {'a', '̈'}: 'ä', {'a', '̊'}: 'å',
+	{'e', '́'}: 'é', {'e', '̀'}: 'è', {'e', '̂'}: 'ê', {'e', '̈'}: 'ë',
+	{'i', '́'}: 'í', {'i', '̀'}: 'ì', {'i', '̂'}: 'î', {'i', '̈'}: 'ï',
+	{'o', '́'}: 'ó', {'o', '̀'}: 'ò', {'o', '̂'}: 'ô', {'o', '̃'}: 'õ', {'o', '̈'}: 'ö',
+	{'u', '́'}: 'ú', {'u', '̀'}: 'ù', {'u', '̂'}: 'û', {'u', '̈'}: 'ü',
+	{'n', '̃'}: 'ñ',
+	{'c', '̧'}: 'ç',
+	{'y', '́'}: 'ý', {'y', '̈'}: 'ÿ',
+	{'A', '́'}: 'Á', {'A', '̀'}: 'À', {'A', '̂'}: 'Â', {'A', '̃'}: 'Ã', {'A', '̈'}: 'Ä', {'A', '̊'}: 'Å',
+	{'E', '́'}: 'É', {'E', '̀'}: 'È', {'E', '̂'}: 'Ê', {'E', '̈'}: 'Ë',
+	{'I', '́'}: 'Í', {'I', '̀'}: 'Ì', {'I', '̂'}: 'Î', {'I', '̈'}: 'Ï',
+	{'O', '́'}: 'Ó', {'O', '̀'}: 'Ò', {'O', '̂'}: 'Ô', {'O', '̃'}: 'Õ', {'O', '̈'}: 'Ö',
+	{'U', '́'}: 'Ú', {'U', '̀'}: 'Ù', {'U', '̂'}: 'Û', {'U', '̈'}: 'Ü',
+	{'N', '̃'}: 'Ñ',
+	{'C', '̧'}: 'Ç',
+}
+
+// NormalizeNFC composes a base Latin letter followed by one of the common
+// combining diacritics (U+0300-U+030A, U+0327) into its precomposed form.
+// Runes outside that common case, including ones already precomposed,
+// pass through unchanged; see the package doc for the scope of this
+// approximation.
+func NormalizeNFC(s string) string {
+	runes := []rune(s)
+	result := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		if i+1 < len(runes) {
+			if composed, ok := combiningToPrecomposed[[2]rune{runes[i], runes[i+1]}]; ok {
+				result = append(result, composed)
+				i++
+				continue
+			}
+		}
+		result = append(result, runes[i])
+	}
+	return string(result)
+}
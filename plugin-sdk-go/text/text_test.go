@@ -0,0 +1,95 @@
+package text
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUpperLower(t *testing.T) {
+	cases := []struct {
+		in, upper, lower string
+	}{
+		{"hello", "HELLO", "hello"},
+		// CJK has no case, round-trips unchanged
+		{"你好", "你好", "你好"},
+		// precomposed é
+		{"café", "CAFÉ", "café"},
+		// emoji is untouched
+		{"\U0001F600 grin", "\U0001F600 GRIN", "\U0001F600 grin"},
+	}
+	for _, c := range cases {
+		if got := Upper(c.in); got != c.upper {
+			t.Errorf("Upper(%q) = %q, want %q", c.in, got, c.upper)
+		}
+		if got := Lower(c.in); got != c.lower {
+			t.Errorf("Lower(%q) = %q, want %q", c.in, got, c.lower)
+		}
+	}
+}
+
+func TestTitle(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"hello world", "Hello World"},
+		{"東京 tower", "東京 Tower"},
+		{"\U0001F600 grin", "\U0001F600 Grin"},
+	}
+	for _, c := range cases {
+		if got := Title(c.in); got != c.want {
+			t.Errorf("Title(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestTrimSpace(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"  hello  ", "hello"},
+		// ideographic space
+		{"　你好　", "你好"},
+		{"\U0001F600", "\U0001F600"},
+	}
+	for _, c := range cases {
+		if got := TrimSpace(c.in); got != c.want {
+			t.Errorf("TrimSpace(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestReverseRunes(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"hello", "olleh"},
+		{"日本語", "語本日"},
+		{"a\U0001F600b", "b\U0001F600a"},
+	}
+	for _, c := range cases {
+		got := ReverseRunes(c.in)
+		if got != c.want {
+			t.Errorf("ReverseRunes(%q) = %q, want %q", c.in, got, c.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("ReverseRunes(%q) = %q is not valid UTF-8", c.in, got)
+		}
+	}
+}
+
+func TestNormalizeNFC(t *testing.T) {
+	eAcuteDecomposed := "e" + "\u0301" // e + combining acute accent
+	eAcutePrecomposed := "\u00e9"      // é
+	nTildeDecomposed := "n" + "\u0303" // n + combining tilde
+	nTildePrecomposed := "\u00f1"      // ñ
+
+	cases := []struct{ in, want string }{
+		{eAcuteDecomposed, eAcutePrecomposed},
+		// accent at the end of a word
+		{"caf" + eAcuteDecomposed, "caf" + eAcutePrecomposed},
+		{nTildeDecomposed, nTildePrecomposed},
+		// already precomposed, unchanged
+		{eAcutePrecomposed, eAcutePrecomposed},
+		// no combining marks, untouched
+		{"日本語", "日本語"},
+	}
+	for _, c := range cases {
+		if got := NormalizeNFC(c.in); got != c.want {
+			t.Errorf("NormalizeNFC(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}